@@ -0,0 +1,253 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errInvalidFilename is returned when an attachment name fails path-traversal validation.
+var errInvalidFilename = errors.New("invalid filename")
+
+// Attachment is a file uploaded against a note.
+type Attachment struct {
+	Filename   string    `json:"filename"`
+	Size       int64     `json:"size"`
+	Mime       string    `json:"mime"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// allowedAttachmentExts is the extension allowlist for uploads.
+var allowedAttachmentExts = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".pdf":  true,
+	".txt":  true,
+	".md":   true,
+}
+
+// attachmentsDir resolves where uploaded files are stored, honoring NOTES_ATTACHMENTS_DIR.
+func attachmentsDir() string {
+	if dir := os.Getenv("NOTES_ATTACHMENTS_DIR"); dir != "" {
+		return dir
+	}
+	return "attachments"
+}
+
+// noteDir returns the on-disk directory holding a given note's attachments.
+func noteDir(noteID int) string {
+	return filepath.Join(attachmentsDir(), strconv.Itoa(noteID))
+}
+
+// uploadAttachment godoc
+// @Summary Upload an attachment
+// @Description Upload a file against a note owned by the logged-in user
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Note ID"
+// @Param file formData file true "File to upload"
+// @Success 201 {object} Attachment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/attachments [post]
+func (a *App) uploadAttachment(c *gin.Context) {
+	userID := currentUserID(c)
+
+	id, err := parseIDParam(c)
+	if err != nil {
+		return
+	}
+
+	if !a.noteBelongsToUser(id, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	if file.Size > maxUploadSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file exceeds max upload size"})
+		return
+	}
+
+	name := filepath.Base(file.Filename)
+	if strings.Contains(file.Filename, "..") || strings.ContainsAny(file.Filename, "/\\") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	if !allowedAttachmentExts[ext] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File type not allowed"})
+		return
+	}
+
+	dir := noteDir(id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	dest := filepath.Join(dir, name)
+	if err := c.SaveUploadedFile(file, dest); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	mime := file.Header.Get("Content-Type")
+	_, err = a.db.Exec(`INSERT OR REPLACE INTO attachments (note_id, filename, size, mime) VALUES (?, ?, ?, ?)`,
+		id, name, file.Size, mime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"filename": name, "size": file.Size, "mime": mime})
+}
+
+// downloadAttachment godoc
+// @Summary Download an attachment
+// @Description Download a previously uploaded file belonging to a note owned by the logged-in user
+// @Tags attachments
+// @Produce application/octet-stream
+// @Param id path int true "Note ID"
+// @Param name path string true "Attachment filename"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notes/{id}/attachments/{name} [get]
+func (a *App) downloadAttachment(c *gin.Context) {
+	userID := currentUserID(c)
+
+	id, err := parseIDParam(c)
+	if err != nil {
+		return
+	}
+
+	if !a.noteBelongsToUser(id, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	name, err := safeAttachmentName(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	var exists bool
+	if err := a.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM attachments WHERE note_id = ? AND filename = ?)`, id, name).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+
+	c.File(filepath.Join(noteDir(id), name))
+}
+
+// deleteAttachment godoc
+// @Summary Delete an attachment
+// @Description Remove an uploaded file from a note owned by the logged-in user
+// @Tags attachments
+// @Produce json
+// @Param id path int true "Note ID"
+// @Param name path string true "Attachment filename"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes/{id}/attachments/{name} [delete]
+func (a *App) deleteAttachment(c *gin.Context) {
+	userID := currentUserID(c)
+
+	id, err := parseIDParam(c)
+	if err != nil {
+		return
+	}
+
+	if !a.noteBelongsToUser(id, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	name, err := safeAttachmentName(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	res, err := a.db.Exec(`DELETE FROM attachments WHERE note_id = ? AND filename = ?`, id, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+
+	if err := os.Remove(filepath.Join(noteDir(id), name)); err != nil && !os.IsNotExist(err) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
+}
+
+// safeAttachmentName reads the :name param and rejects path traversal.
+func safeAttachmentName(c *gin.Context) (string, error) {
+	name := c.Param("name")
+	if name == "" || name != filepath.Base(name) || strings.Contains(name, "..") {
+		return "", errInvalidFilename
+	}
+	return name, nil
+}
+
+// noteBelongsToUser reports whether note id is owned by userID.
+func (a *App) noteBelongsToUser(noteID, userID int) bool {
+	var exists bool
+	_ = a.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM notes WHERE id = ? AND user_id = ?)`, noteID, userID).Scan(&exists)
+	return exists
+}
+
+// attachmentsForNote loads the attachment manifest for a note.
+func (a *App) attachmentsForNote(noteID int) ([]Attachment, error) {
+	rows, err := a.db.Query(`SELECT filename, size, mime, uploaded_at FROM attachments WHERE note_id = ? ORDER BY uploaded_at`, noteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []Attachment{}
+	for rows.Next() {
+		var att Attachment
+		if err := rows.Scan(&att.Filename, &att.Size, &att.Mime, &att.UploadedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, att)
+	}
+	return result, nil
+}
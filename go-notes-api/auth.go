@@ -0,0 +1,179 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// credentials is the body shape for /auth/register, /auth/login, and /setup.
+type credentials struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ================== Setup ==================
+
+// setup godoc
+// @Summary First-run setup
+// @Description Provisions the initial admin account. Refuses once any user exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body credentials true "Admin credentials"
+// @Success 201 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /setup [post]
+func (a *App) setup(c *gin.Context) {
+	var count int
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Setup has already been completed"})
+		return
+	}
+
+	var creds credentials
+	if err := c.ShouldBindJSON(&creds); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := a.createUser(creds.Username, creds.Password); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Admin account created"})
+}
+
+// ================== Auth handlers ==================
+
+// register godoc
+// @Summary Register a new user
+// @Description Create a new user account with a bcrypt-hashed password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body credentials true "New account credentials"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /auth/register [post]
+func (a *App) register(c *gin.Context) {
+	var creds credentials
+	if err := c.ShouldBindJSON(&creds); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := a.createUser(creds.Username, creds.Password)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username already taken"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "username": creds.Username})
+}
+
+// login godoc
+// @Summary Log in
+// @Description Authenticate and start a cookie session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body credentials true "Account credentials"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/login [post]
+func (a *App) login(c *gin.Context) {
+	var creds credentials
+	if err := c.ShouldBindJSON(&creds); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var id int
+	var hash string
+	err := a.db.QueryRow(`SELECT id, password_hash FROM users WHERE username = ?`, creds.Username).Scan(&id, &hash)
+	if err == sql.ErrNoRows || (err == nil && bcrypt.CompareHashAndPassword([]byte(hash), []byte(creds.Password)) != nil) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set("userid", id)
+	if err := session.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged in"})
+}
+
+// logout godoc
+// @Summary Log out
+// @Description Clear the current session
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/logout [post]
+func (a *App) logout(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Clear()
+	if err := session.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// createUser hashes password and inserts a new user row, returning its ID.
+func (a *App) createUser(username, password string) (int64, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := a.db.Exec(`INSERT INTO users (username, password_hash) VALUES (?, ?)`, username, string(hash))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// ================== Middleware ==================
+
+// AuthRequiredMiddleware aborts with 401 unless the session carries a userid.
+func AuthRequiredMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := sessions.Default(c)
+		if session.Get("userid") == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentUserID reads the authenticated user's ID from the session.
+func currentUserID(c *gin.Context) int {
+	session := sessions.Default(c)
+	return session.Get("userid").(int)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ================== Database ==================
+
+// dbFile is the SQLite file path; override with the NOTES_DB_FILE env var.
+const dbFile = "notes.db"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS users (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	username      TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS notes (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id    INTEGER NOT NULL REFERENCES users(id),
+	title      TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS attachments (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	note_id     INTEGER NOT NULL REFERENCES notes(id),
+	filename    TEXT NOT NULL,
+	size        INTEGER NOT NULL,
+	mime        TEXT NOT NULL,
+	uploaded_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(note_id, filename)
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+	id      INTEGER PRIMARY KEY AUTOINCREMENT,
+	note_id INTEGER NOT NULL REFERENCES notes(id),
+	tag     TEXT NOT NULL,
+	UNIQUE(note_id, tag)
+);
+`
+
+// openDB opens (creating if needed) the SQLite database at path and makes
+// sure the schema is in place.
+func openDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := migrateUserID(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// migrateUserID adds the notes.user_id column when opening a database that
+// predates session-based auth (chunk0-1). CREATE TABLE IF NOT EXISTS is a
+// no-op against such a database, so the column has to be backfilled here
+// instead - existing notes are assigned to no one (user_id = 0) and will no
+// longer be visible until reassigned, since every query now scopes by
+// session user.
+func migrateUserID(db *sql.DB) error {
+	rows, err := db.Query(`PRAGMA table_info(notes)`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name, typ string
+			notNull   int
+			dfltValue interface{}
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == "user_id" {
+			return rows.Err()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`ALTER TABLE notes ADD COLUMN user_id INTEGER NOT NULL DEFAULT 0`)
+	return err
+}
+
+// dbFilePath resolves the SQLite file path, honoring NOTES_DB_FILE.
+func dbFilePath() string {
+	if path := os.Getenv("NOTES_DB_FILE"); path != "" {
+		return path
+	}
+	return dbFile
+}
@@ -1,260 +1,418 @@
-package main
-
-import (
-	"encoding/json"
-	"log"
-	"net/http"
-	"os"
-	"strconv"
-	"sync"
-
-	"github.com/gin-gonic/gin"
-)
-
-// Note struct - ek note ka data
-type Note struct {
-	ID      int    `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
-}
-
-// In-memory notes (with file persistence)
-var (
-	notes  = []Note{}
-	nextID = 1
-	mu     sync.Mutex
-)
-
-const dataFile = "notes.json"
-
-func main() {
-	// Load notes from file at startup (permanent storage)
-	if err := loadNotesFromFile(); err != nil {
-		log.Println("Could not load notes from file:", err)
-	}
-
-	r := gin.Default()
-
-	// CORS + custom logging middleware
-	r.Use(corsMiddleware())
-
-	// Routes
-	r.GET("/notes", getNotes)          // saare notes
-	r.POST("/notes", addNote)          // naya note add
-	r.GET("/notes/:id", getNoteByID)   // id se ek note
-	r.PUT("/notes/:id", updateNote)    // note update
-	r.DELETE("/notes/:id", deleteNote) // note delete
-
-	// Simple docs endpoint (Swagger-style info)
-	r.GET("/docs", docsHandler)
-
-	// Simple frontend serve
-	r.StaticFile("/", "./frontend.html")
-
-	log.Println("Server listening on :8080")
-	if err := r.Run(":8080"); err != nil {
-		log.Fatal("Server failed:", err)
-	}
-}
-
-// ================== Handlers ==================
-
-// GET /notes
-func getNotes(c *gin.Context) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	c.JSON(http.StatusOK, notes)
-}
-
-// POST /notes
-func addNote(c *gin.Context) {
-	var newNote Note
-
-	if err := c.ShouldBindJSON(&newNote); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	mu.Lock()
-	defer mu.Unlock()
-
-	newNote.ID = nextID
-	nextID++
-	notes = append(notes, newNote)
-
-	if err := saveNotesToFile(); err != nil {
-		log.Println("Failed to save notes:", err)
-	}
-
-	c.JSON(http.StatusCreated, newNote)
-}
-
-// GET /notes/:id
-func getNoteByID(c *gin.Context) {
-	id, err := parseIDParam(c)
-	if err != nil {
-		return
-	}
-
-	mu.Lock()
-	defer mu.Unlock()
-
-	for _, note := range notes {
-		if note.ID == id {
-			c.JSON(http.StatusOK, note)
-			return
-		}
-	}
-
-	c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
-}
-
-// PUT /notes/:id  (Update note)
-func updateNote(c *gin.Context) {
-	id, err := parseIDParam(c)
-	if err != nil {
-		return
-	}
-
-	var updated Note
-	if err := c.ShouldBindJSON(&updated); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	mu.Lock()
-	defer mu.Unlock()
-
-	for i, note := range notes {
-		if note.ID == id {
-			notes[i].Title = updated.Title
-			notes[i].Content = updated.Content
-
-			if err := saveNotesToFile(); err != nil {
-				log.Println("Failed to save notes:", err)
-			}
-
-			c.JSON(http.StatusOK, notes[i])
-			return
-		}
-	}
-
-	c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
-}
-
-// DELETE /notes/:id
-func deleteNote(c *gin.Context) {
-	id, err := parseIDParam(c)
-	if err != nil {
-		return
-	}
-
-	mu.Lock()
-	defer mu.Unlock()
-
-	for i, note := range notes {
-		if note.ID == id {
-			notes = append(notes[:i], notes[i+1:]...)
-
-			if err := saveNotesToFile(); err != nil {
-				log.Println("Failed to save notes:", err)
-			}
-
-			c.JSON(http.StatusOK, gin.H{"message": "Note deleted"})
-			return
-		}
-	}
-
-	c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
-}
-
-// ================== Helper functions ==================
-
-func parseIDParam(c *gin.Context) (int, error) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return 0, err
-	}
-	return id, nil
-}
-
-// ================== Persistence (file as DB) ==================
-
-func loadNotesFromFile() error {
-	file, err := os.Open(dataFile)
-	if os.IsNotExist(err) {
-		// No existing file - first run
-		return nil
-	}
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	var loaded []Note
-	if err := json.NewDecoder(file).Decode(&loaded); err != nil {
-		return err
-	}
-
-	notes = loaded
-	// Set nextID correctly
-	maxID := 0
-	for _, n := range notes {
-		if n.ID > maxID {
-			maxID = n.ID
-		}
-	}
-	nextID = maxID + 1
-
-	return nil
-}
-
-func saveNotesToFile() error {
-	file, err := os.Create(dataFile) // truncate + create
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	enc := json.NewEncoder(file)
-	enc.SetIndent("", "  ")
-	return enc.Encode(notes)
-}
-
-// ================== Docs Handler ==================
-
-func docsHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"title":       "Go Notes API",
-		"description": "Simple REST API for managing notes (Go + Gin)",
-		"endpoints": []gin.H{
-			{"method": "GET", "path": "/notes", "description": "Get all notes"},
-			{"method": "POST", "path": "/notes", "description": "Create a new note"},
-			{"method": "GET", "path": "/notes/:id", "description": "Get note by ID"},
-			{"method": "PUT", "path": "/notes/:id", "description": "Update note by ID"},
-			{"method": "DELETE", "path": "/notes/:id", "description": "Delete note by ID"},
-		},
-	})
-}
-
-// ================== Middleware ==================
-
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		log.Printf("%s %s\n", c.Request.Method, c.Request.URL.Path)
-
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if c.Request.Method == http.MethodOptions {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-
-		c.Next()
-	}
-}
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+
+	"notes-api/docs"
+)
+
+// Note struct - ek note ka data
+type Note struct {
+	ID          int          `json:"id"`
+	UserID      int          `json:"user_id"`
+	Title       string       `json:"title"`
+	Content     string       `json:"content"`
+	Tags        []string     `json:"tags"`
+	Attachments []Attachment `json:"attachments"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// App holds the shared dependencies handlers need (just the DB for now).
+type App struct {
+	db *sql.DB
+}
+
+//go:generate swag init -g main.go -o docs
+
+// @title Go Notes API
+// @version 1.0
+// @description Simple REST API for managing notes (Go + Gin)
+// @host localhost:8080
+// @BasePath /
+func main() {
+	db, err := openDB(dbFilePath())
+	if err != nil {
+		log.Fatal("Could not open database:", err)
+	}
+	defer db.Close()
+
+	app := &App{db: db}
+
+	r := gin.Default()
+	r.MaxMultipartMemory = maxUploadSize()
+
+	// Request ID -> structured logging -> metrics -> CORS
+	r.Use(requestIDMiddleware())
+	r.Use(loggingMiddleware())
+	r.Use(metricsMiddleware())
+	r.Use(corsMiddleware())
+
+	// Cookie-backed sessions, keyed off a secret set via SESSION_SECRET
+	store := cookie.NewStore([]byte(sessionSecret()))
+	r.Use(sessions.Sessions("notes_session", store))
+
+	// First-run provisioning
+	r.POST("/setup", app.setup)
+
+	// Auth routes
+	auth := r.Group("/auth")
+	auth.POST("/register", app.register)
+	auth.POST("/login", app.login)
+	auth.POST("/logout", app.logout)
+
+	// Notes routes - scoped to the logged-in user
+	notesGroup := r.Group("/notes")
+	notesGroup.Use(AuthRequiredMiddleware())
+	notesGroup.GET("", app.getNotes)                   // saare notes
+	notesGroup.POST("", app.addNote)                   // naya note add
+	notesGroup.GET("/search", app.searchNotes)         // search title/content, filter by tag
+	notesGroup.GET("/:id", app.getNoteByID)            // id se ek note
+	notesGroup.PUT("/:id", app.updateNote)             // note update
+	notesGroup.DELETE("/:id", app.deleteNote)          // note delete
+	notesGroup.POST("/:id/tags", app.addTag)           // tag a note
+	notesGroup.DELETE("/:id/tags/:tag", app.removeTag) // untag a note
+
+	// Attachments - nested under their note
+	notesGroup.POST("/:id/attachments", app.uploadAttachment)
+	notesGroup.GET("/:id/attachments/:name", app.downloadAttachment)
+	notesGroup.DELETE("/:id/attachments/:name", app.deleteAttachment)
+
+	// Tag aggregates across all of the logged-in user's notes
+	r.GET("/tags", AuthRequiredMiddleware(), app.listTags)
+
+	// Prometheus metrics
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// Swagger UI + machine-readable spec. docs/docs.go is generated by
+	// `go generate ./...` (see the directive above) from the @Summary/@Param/
+	// @Success annotations on the handlers - do not hand edit it.
+	r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.GET("/swagger.json", func(c *gin.Context) {
+		spec := docs.SwaggerInfo.ReadDoc()
+		c.Data(http.StatusOK, "application/json", []byte(spec))
+	})
+
+	// Simple frontend serve
+	r.StaticFile("/", "./frontend.html")
+
+	log.Println("Server listening on :8080")
+	if err := r.Run(":8080"); err != nil {
+		log.Fatal("Server failed:", err)
+	}
+}
+
+// ================== Handlers ==================
+
+// getNotes godoc
+// @Summary List notes
+// @Description Get all notes owned by the logged-in user
+// @Tags notes
+// @Produce json
+// @Success 200 {array} Note
+// @Failure 500 {object} map[string]string
+// @Router /notes [get]
+func (a *App) getNotes(c *gin.Context) {
+	userID := currentUserID(c)
+
+	rows, err := a.db.Query(`SELECT id, user_id, title, content, created_at, updated_at FROM notes WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	result := []Note{}
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Title, &n.Content, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		attachments, err := a.attachmentsForNote(n.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		n.Attachments = attachments
+
+		tags, err := a.tagsForNote(n.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		n.Tags = tags
+
+		result = append(result, n)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// addNote godoc
+// @Summary Create a note
+// @Description Create a new note for the logged-in user
+// @Tags notes
+// @Accept json
+// @Produce json
+// @Param note body Note true "Note to create"
+// @Success 201 {object} Note
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notes [post]
+func (a *App) addNote(c *gin.Context) {
+	userID := currentUserID(c)
+
+	var newNote Note
+	if err := c.ShouldBindJSON(&newNote); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := a.db.Exec(`INSERT INTO notes (user_id, title, content) VALUES (?, ?, ?)`, userID, newNote.Title, newNote.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := a.db.QueryRow(`SELECT id, user_id, title, content, created_at, updated_at FROM notes WHERE id = ?`, id).
+		Scan(&newNote.ID, &newNote.UserID, &newNote.Title, &newNote.Content, &newNote.CreatedAt, &newNote.UpdatedAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	newNote.Attachments = []Attachment{}
+	newNote.Tags = []string{}
+
+	c.JSON(http.StatusCreated, newNote)
+}
+
+// getNoteByID godoc
+// @Summary Get a note
+// @Description Get a single note by ID, if it belongs to the logged-in user
+// @Tags notes
+// @Produce json
+// @Param id path int true "Note ID"
+// @Success 200 {object} Note
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notes/{id} [get]
+func (a *App) getNoteByID(c *gin.Context) {
+	userID := currentUserID(c)
+
+	id, err := parseIDParam(c)
+	if err != nil {
+		return
+	}
+
+	var n Note
+	err = a.db.QueryRow(`SELECT id, user_id, title, content, created_at, updated_at FROM notes WHERE id = ? AND user_id = ?`, id, userID).
+		Scan(&n.ID, &n.UserID, &n.Title, &n.Content, &n.CreatedAt, &n.UpdatedAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachments, err := a.attachmentsForNote(n.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	n.Attachments = attachments
+
+	tags, err := a.tagsForNote(n.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	n.Tags = tags
+
+	c.JSON(http.StatusOK, n)
+}
+
+// updateNote godoc
+// @Summary Update a note
+// @Description Update the title/content of a note owned by the logged-in user
+// @Tags notes
+// @Accept json
+// @Produce json
+// @Param id path int true "Note ID"
+// @Param note body Note true "Updated note"
+// @Success 200 {object} Note
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notes/{id} [put]
+func (a *App) updateNote(c *gin.Context) {
+	userID := currentUserID(c)
+
+	id, err := parseIDParam(c)
+	if err != nil {
+		return
+	}
+
+	var updated Note
+	if err := c.ShouldBindJSON(&updated); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := a.db.Exec(`UPDATE notes SET title = ?, content = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?`,
+		updated.Title, updated.Content, id, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	var n Note
+	if err := a.db.QueryRow(`SELECT id, user_id, title, content, created_at, updated_at FROM notes WHERE id = ?`, id).
+		Scan(&n.ID, &n.UserID, &n.Title, &n.Content, &n.CreatedAt, &n.UpdatedAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachments, err := a.attachmentsForNote(n.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	n.Attachments = attachments
+
+	tags, err := a.tagsForNote(n.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	n.Tags = tags
+
+	c.JSON(http.StatusOK, n)
+}
+
+// deleteNote godoc
+// @Summary Delete a note
+// @Description Delete a note owned by the logged-in user
+// @Tags notes
+// @Produce json
+// @Param id path int true "Note ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notes/{id} [delete]
+func (a *App) deleteNote(c *gin.Context) {
+	userID := currentUserID(c)
+
+	id, err := parseIDParam(c)
+	if err != nil {
+		return
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM notes WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	// SQLite doesn't enforce the REFERENCES in the schema without
+	// PRAGMA foreign_keys=ON, so tags/attachments rows have to be cleaned
+	// up alongside the note or they're orphaned.
+	if _, err := tx.Exec(`DELETE FROM tags WHERE note_id = ?`, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := tx.Exec(`DELETE FROM attachments WHERE note_id = ?`, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := os.RemoveAll(noteDir(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Note deleted"})
+}
+
+// ================== Helper functions ==================
+
+func parseIDParam(c *gin.Context) (int, error) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, err
+	}
+	return id, nil
+}
+
+// sessionSecret resolves the cookie-session signing key, honoring SESSION_SECRET.
+func sessionSecret() string {
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		return secret
+	}
+	return "dev-secret-change-me"
+}
+
+// maxUploadSize resolves the multipart memory/size cap in bytes, honoring
+// NOTES_MAX_UPLOAD_BYTES. Defaults to 10 MiB.
+func maxUploadSize() int64 {
+	if raw := os.Getenv("NOTES_MAX_UPLOAD_BYTES"); raw != "" {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n
+		}
+	}
+	return 10 << 20
+}
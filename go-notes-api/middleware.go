@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.New()
+
+func init() {
+	logger.SetFormatter(&logrus.JSONFormatter{})
+}
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests processed.",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "http_request_duration_seconds",
+		Help: "HTTP request latency in seconds.",
+	}, []string{"method", "path", "status"})
+)
+
+// ================== Middleware ==================
+
+// requestIDMiddleware reuses the incoming X-Request-ID header when one is sent,
+// otherwise assigns a new UUID, and echoes the ID in the X-Request-ID response header.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		c.Set("request_id", id)
+		c.Writer.Header().Set("X-Request-ID", id)
+
+		c.Next()
+	}
+}
+
+// loggingMiddleware writes one structured JSON log line per request.
+func loggingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		logger.WithFields(logrus.Fields{
+			"request_id": c.GetString("request_id"),
+			"method":     c.Request.Method,
+			"path":       c.FullPath(),
+			"status":     c.Writer.Status(),
+			"latency":    time.Since(start).String(),
+		}).Info("request handled")
+	}
+}
+
+// metricsMiddleware records Prometheus counters/histograms per request.
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequestsTotal.WithLabelValues(c.Request.Method, path, status).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, path, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// corsMiddleware sets CORS headers for the origins configured via CORS_ALLOWED_ORIGINS
+// (comma-separated; defaults to "*").
+func corsMiddleware() gin.HandlerFunc {
+	origins := allowedOrigins()
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+
+		switch {
+		case len(origins) == 1 && origins[0] == "*":
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && containsOrigin(origins, origin):
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Set("Vary", "Origin")
+		}
+
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// allowedOrigins resolves CORS_ALLOWED_ORIGINS into a list, defaulting to ["*"].
+func allowedOrigins() []string {
+	raw := os.Getenv("CORS_ALLOWED_ORIGINS")
+	if raw == "" {
+		return []string{"*"}
+	}
+
+	var origins []string
+	for _, o := range strings.Split(raw, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins = append(origins, o)
+		}
+	}
+	return origins
+}
+
+func containsOrigin(origins []string, origin string) bool {
+	for _, o := range origins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// searchNotes godoc
+// @Summary Search notes
+// @Description Case-insensitive substring search over title/content, filterable by tag, with pagination
+// @Tags notes
+// @Produce json
+// @Param q query string false "Search term"
+// @Param tag query []string false "Filter by tag (repeatable)" collectionFormat(multi)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Page offset"
+// @Success 200 {array} Note
+// @Failure 500 {object} map[string]string
+// @Router /notes/search [get]
+func (a *App) searchNotes(c *gin.Context) {
+	userID := currentUserID(c)
+
+	q := strings.TrimSpace(c.Query("q"))
+	tags := c.QueryArray("tag")
+	limit, offset := parsePagination(c)
+
+	args := []interface{}{userID}
+	query := `SELECT DISTINCT n.id, n.user_id, n.title, n.content, n.created_at, n.updated_at FROM notes n`
+	if len(tags) > 0 {
+		query += ` JOIN tags t ON t.note_id = n.id`
+	}
+	query += ` WHERE n.user_id = ?`
+
+	if q != "" {
+		query += ` AND (n.title LIKE ? ESCAPE '\' OR n.content LIKE ? ESCAPE '\')`
+		like := "%" + escapeLike(q) + "%"
+		args = append(args, like, like)
+	}
+	if len(tags) > 0 {
+		placeholders := make([]string, len(tags))
+		for i, tag := range tags {
+			placeholders[i] = "?"
+			args = append(args, tag)
+		}
+		query += ` AND t.tag IN (` + strings.Join(placeholders, ",") + `)`
+	}
+
+	countQuery := `SELECT COUNT(*) FROM (` + query + `)`
+	var total int
+	if err := a.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	query += ` ORDER BY n.id LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	result := []Note{}
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Title, &n.Content, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		noteTags, err := a.tagsForNote(n.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		n.Tags = noteTags
+
+		attachments, err := a.attachmentsForNote(n.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		n.Attachments = attachments
+
+		result = append(result, n)
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.JSON(http.StatusOK, result)
+}
+
+// addTag godoc
+// @Summary Tag a note
+// @Description Attach a tag to a note owned by the logged-in user
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Note ID"
+// @Param tag body map[string]string true "Tag to add, as a JSON object with a tag field"
+// @Success 201 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notes/{id}/tags [post]
+func (a *App) addTag(c *gin.Context) {
+	userID := currentUserID(c)
+
+	id, err := parseIDParam(c)
+	if err != nil {
+		return
+	}
+
+	if !a.noteBelongsToUser(id, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	var body struct {
+		Tag string `json:"tag" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := a.db.Exec(`INSERT OR IGNORE INTO tags (note_id, tag) VALUES (?, ?)`, id, body.Tag); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"tag": body.Tag})
+}
+
+// removeTag godoc
+// @Summary Untag a note
+// @Description Remove a tag from a note owned by the logged-in user
+// @Tags tags
+// @Produce json
+// @Param id path int true "Note ID"
+// @Param tag path string true "Tag to remove"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notes/{id}/tags/{tag} [delete]
+func (a *App) removeTag(c *gin.Context) {
+	userID := currentUserID(c)
+
+	id, err := parseIDParam(c)
+	if err != nil {
+		return
+	}
+
+	if !a.noteBelongsToUser(id, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	res, err := a.db.Exec(`DELETE FROM tags WHERE note_id = ? AND tag = ?`, id, c.Param("tag"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag removed"})
+}
+
+// listTags godoc
+// @Summary List tags
+// @Description Returns tag -> note count aggregates for the logged-in user
+// @Tags tags
+// @Produce json
+// @Success 200 {array} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /tags [get]
+func (a *App) listTags(c *gin.Context) {
+	userID := currentUserID(c)
+
+	rows, err := a.db.Query(`
+		SELECT t.tag, COUNT(*) FROM tags t
+		JOIN notes n ON n.id = t.note_id
+		WHERE n.user_id = ?
+		GROUP BY t.tag
+		ORDER BY t.tag`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	result := []gin.H{}
+	for rows.Next() {
+		var tag string
+		var count int
+		if err := rows.Scan(&tag, &count); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		result = append(result, gin.H{"tag": tag, "count": count})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// tagsForNote loads the tag list for a note.
+func (a *App) tagsForNote(noteID int) ([]string, error) {
+	rows, err := a.db.Query(`SELECT tag FROM tags WHERE note_id = ? ORDER BY tag`, noteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		result = append(result, tag)
+	}
+	return result, nil
+}
+
+// parsePagination reads limit/offset query params, applying sane defaults and bounds.
+func parsePagination(c *gin.Context) (limit, offset int) {
+	limit = defaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			limit = n
+		}
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	if raw := c.Query("offset"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	return limit, offset
+}
+
+// escapeLike escapes SQLite LIKE wildcards in user input.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}